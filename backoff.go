@@ -0,0 +1,186 @@
+package elastic
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Backoff is an interface for different types of backoff strategies.
+// It is consulted whenever a request to Elasticsearch needs to be
+// retried, e.g. from PerformRequest or the BulkProcessor.
+type Backoff interface {
+	// Next returns the duration to wait
+	// and whether a retry should be attempted at all for the given
+	// retry count (starting at 0 for the first retry).
+	Next(retry int) (time.Duration, bool)
+}
+
+// -- StopBackoff --
+
+// StopBackoff is a fixed backoff policy that always returns false for
+// Next(), meaning that the operation is never retried.
+type StopBackoff struct{}
+
+// NewStopBackoff returns a new StopBackoff.
+func NewStopBackoff() *StopBackoff {
+	return &StopBackoff{}
+}
+
+// Next always returns false, so that the caller stops retrying.
+func (b *StopBackoff) Next(retry int) (time.Duration, bool) {
+	return 0, false
+}
+
+// -- ConstantBackoff --
+
+// ConstantBackoff is a backoff policy that always returns the same
+// delay, and retries forever.
+type ConstantBackoff struct {
+	interval time.Duration
+}
+
+// NewConstantBackoff returns a new ConstantBackoff with the given
+// fixed interval.
+func NewConstantBackoff(interval time.Duration) *ConstantBackoff {
+	return &ConstantBackoff{interval: interval}
+}
+
+// Next returns the fixed interval and true, indicating that the
+// caller should retry after waiting.
+func (b *ConstantBackoff) Next(retry int) (time.Duration, bool) {
+	return b.interval, true
+}
+
+// -- SimpleBackoff --
+
+// SimpleBackoff takes a list of fixed durations and applies them one
+// by one. Once the list is exhausted, it stops retrying.
+type SimpleBackoff struct {
+	mu     sync.Mutex
+	ticks  []int
+	jitter bool
+}
+
+// NewSimpleBackoff creates a backoff policy that applies a fixed set of
+// durations in order, e.g. NewSimpleBackoff(1, 2, 3) will wait 1ms, then
+// 2ms, then 3ms, and stop retrying after that.
+func NewSimpleBackoff(ticks ...int) *SimpleBackoff {
+	return &SimpleBackoff{ticks: ticks}
+}
+
+// Next returns the duration at position retry, or false once the
+// list of ticks has been exhausted.
+func (b *SimpleBackoff) Next(retry int) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if retry < 0 || retry >= len(b.ticks) {
+		return 0, false
+	}
+
+	ms := b.ticks[retry]
+	if b.jitter {
+		ms = jitter(ms)
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// -- ExponentialBackoff --
+
+// ExponentialBackoff implements an exponential backoff policy with
+// full jitter, doubling the wait time on every successive retry
+// starting at initial, up to a maximum of max. Next depends only on
+// the retry count it is given, so a single instance may be shared
+// across unrelated, concurrent retry sequences (e.g. the one
+// returned by defaultRetrier, or a revivalDelay computation) without
+// their retry counts interfering with each other.
+type ExponentialBackoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+// NewExponentialBackoff returns a new ExponentialBackoff that starts
+// at initial and never waits longer than max.
+func NewExponentialBackoff(initial, max time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		initial: initial,
+		max:     max,
+	}
+}
+
+// Next computes min(max, initial*2^retry) and returns a random
+// duration between 0 and that value, i.e. full jitter. It never
+// stops retrying.
+func (b *ExponentialBackoff) Next(retry int) (time.Duration, bool) {
+	if retry < 0 {
+		retry = 0
+	}
+
+	wait := b.initial
+	for i := 0; i < retry && wait < b.max; i++ {
+		wait *= 2
+	}
+	if wait > b.max {
+		wait = b.max
+	}
+	if wait <= 0 {
+		return 0, true
+	}
+
+	return time.Duration(rand.Int63n(int64(wait))), true
+}
+
+// jitter returns a random duration (in milliseconds) between 0 and
+// millis.
+func jitter(millis int) int {
+	if millis <= 0 {
+		return 0
+	}
+	return rand.Intn(millis)
+}
+
+// -- Retrier --
+
+// Retrier decides whether to retry a failed request to Elasticsearch,
+// and if so, how long to wait before doing so. It is consulted by
+// Client.PerformRequest on connection errors and 5xx responses. The
+// BulkProcessor does not consult it between its own commit attempts;
+// it waits according to the Backoff set via BulkProcessorService.Backoff.
+type Retrier interface {
+	// Retry is called after a request has failed. It returns the
+	// duration to wait before retrying, whether a retry should be
+	// attempted at all, and an error that, if non-nil, is returned
+	// to the caller instead of retrying.
+	Retry(retry int, req *http.Request, resp *http.Response, err error) (time.Duration, bool, error)
+}
+
+// BackoffRetrier is a Retrier that delegates the wait/stop decision
+// to a Backoff policy and retries on connection errors and 5xx
+// responses.
+type BackoffRetrier struct {
+	backoff Backoff
+}
+
+// NewBackoffRetrier returns a Retrier that uses the given Backoff
+// policy to determine the wait between retries.
+func NewBackoffRetrier(backoff Backoff) *BackoffRetrier {
+	return &BackoffRetrier{backoff: backoff}
+}
+
+// Retry is called by Client.PerformRequest whenever a request fails
+// with a connection error or a 5xx response.
+func (r *BackoffRetrier) Retry(retry int, req *http.Request, resp *http.Response, err error) (time.Duration, bool, error) {
+	if err == nil && resp != nil && resp.StatusCode < 500 {
+		return 0, false, nil
+	}
+	wait, ok := r.backoff.Next(retry)
+	return wait, ok, nil
+}
+
+// defaultRetrier is the Retrier used by Client when none has been
+// set explicitly via SetRetrier.
+func defaultRetrier() Retrier {
+	return NewBackoffRetrier(NewExponentialBackoff(10*time.Millisecond, 30*time.Second))
+}
@@ -0,0 +1,56 @@
+package elastic
+
+import "testing"
+
+func TestExponentialBackoffNextIsStateless(t *testing.T) {
+	b := NewExponentialBackoff(10, 1000)
+
+	// Calling Next with the same retry count must always be bounded
+	// by the same range, regardless of how many times it has been
+	// called before or in what order -- i.e. Next must not carry
+	// state between calls the way a mutable "current" field would.
+	for i := 0; i < 5; i++ {
+		wait, ok := b.Next(2)
+		if !ok {
+			t.Fatalf("Next(2) returned ok=false, want true")
+		}
+		if wait < 0 || wait > 40 {
+			t.Fatalf("Next(2) = %v, want in [0,40]", wait)
+		}
+	}
+
+	// A later call with a smaller retry count must not be affected
+	// by the calls above.
+	wait, _ := b.Next(0)
+	if wait < 0 || wait > 10 {
+		t.Fatalf("Next(0) = %v, want in [0,10]", wait)
+	}
+}
+
+func TestExponentialBackoffNextCapsAtMax(t *testing.T) {
+	b := NewExponentialBackoff(10, 100)
+
+	wait, ok := b.Next(10)
+	if !ok {
+		t.Fatalf("Next(10) returned ok=false, want true")
+	}
+	if wait > 100 {
+		t.Fatalf("Next(10) = %v, want capped at 100", wait)
+	}
+}
+
+func TestExponentialBackoffNextZeroInitialDoesNotPanic(t *testing.T) {
+	b := NewExponentialBackoff(0, 1000)
+
+	if wait, ok := b.Next(0); !ok || wait != 0 {
+		t.Fatalf("Next(0) = (%v, %v), want (0, true)", wait, ok)
+	}
+}
+
+func TestExponentialBackoffNextNegativeRetry(t *testing.T) {
+	b := NewExponentialBackoff(10, 1000)
+
+	if _, ok := b.Next(-1); !ok {
+		t.Fatalf("Next(-1) returned ok=false, want true")
+	}
+}
@@ -0,0 +1,478 @@
+package elastic
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCommitRetries is the number of times a single bulk commit is
+// retried, consulting the backoff policy between attempts, before
+// the error is given up on and returned to the caller.
+const maxCommitRetries = 5
+
+// BulkProcessorService allows to easily process bulk requests. It allows
+// setting policies when to flush new bulk requests, e.g. based on
+// a number of actions, on the size of the actions, and/or to flush
+// periodically. It also allows to control the number of concurrent
+// bulk requests allowed to be executed in parallel.
+//
+// BulkProcessorService, by default, commits either every 1000 requests
+// or when the (estimated) size of the bulk requests exceeds 5 MB. However,
+// it does not by default apply a periodic flush of the bulk request.
+type BulkProcessorService struct {
+	c             *Client
+	name          string
+	numWorkers    int
+	bulkActions   int
+	bulkSize      int
+	flushInterval time.Duration
+	wantStats     bool
+	backoff       Backoff
+	before        BulkBeforeFunc
+	after         BulkAfterFunc
+}
+
+// NewBulkProcessorService creates a new BulkProcessorService.
+func NewBulkProcessorService(client *Client) *BulkProcessorService {
+	return &BulkProcessorService{
+		c:           client,
+		numWorkers:  1,
+		bulkActions: 1000,
+		bulkSize:    5 << 20, // 5 MB
+		wantStats:   true,
+		backoff:     NewExponentialBackoff(10*time.Millisecond, 30*time.Second),
+	}
+}
+
+// BulkBeforeFunc defines the signature of callbacks that are executed
+// before a commit to Elasticsearch.
+type BulkBeforeFunc func(executionId int64, requests []BulkableRequest)
+
+// BulkAfterFunc defines the signature of callbacks that are executed
+// after a commit to Elasticsearch. The err parameter signals an error.
+type BulkAfterFunc func(executionId int64, requests []BulkableRequest, response *BulkResponse, err error)
+
+// Name is an optional name to identify this bulk processor.
+func (s *BulkProcessorService) Name(name string) *BulkProcessorService {
+	s.name = name
+	return s
+}
+
+// Workers is the number of concurrent workers allowed to be
+// executed. Defaults to 1 and should never be less than that.
+func (s *BulkProcessorService) Workers(num int) *BulkProcessorService {
+	s.numWorkers = num
+	return s
+}
+
+// BulkActions specifies when to flush based on the number of actions
+// currently added. Defaults to 1000 and can be set to -1 to disable it.
+func (s *BulkProcessorService) BulkActions(bulkActions int) *BulkProcessorService {
+	s.bulkActions = bulkActions
+	return s
+}
+
+// BulkSize specifies when to flush based on the size (in bytes) of
+// the actions currently added. Defaults to 5 MB and can be set to
+// -1 to disable it.
+func (s *BulkProcessorService) BulkSize(bulkSize int) *BulkProcessorService {
+	s.bulkSize = bulkSize
+	return s
+}
+
+// FlushInterval specifies when to flush at the end of the given interval.
+// This is disabled by default. If you want the bulk processor to
+// operate completely asynchronously, set both BulkActions and BulkSize
+// to -1 and set the FlushInterval to a meaningful value.
+func (s *BulkProcessorService) FlushInterval(interval time.Duration) *BulkProcessorService {
+	s.flushInterval = interval
+	return s
+}
+
+// Stats tells whether to gather statistics while running the
+// bulk processor. Defaults to true.
+func (s *BulkProcessorService) Stats(wantStats bool) *BulkProcessorService {
+	s.wantStats = wantStats
+	return s
+}
+
+// Backoff sets the backoff policy to use between retries of a failed
+// bulk commit. Defaults to an ExponentialBackoff.
+func (s *BulkProcessorService) Backoff(backoff Backoff) *BulkProcessorService {
+	s.backoff = backoff
+	return s
+}
+
+// Before specifies a function to be executed before bulk requests get
+// committed to Elasticsearch.
+func (s *BulkProcessorService) Before(before BulkBeforeFunc) *BulkProcessorService {
+	s.before = before
+	return s
+}
+
+// After specifies a function to be executed when bulk requests have
+// been committed to Elasticsearch, or have failed.
+func (s *BulkProcessorService) After(after BulkAfterFunc) *BulkProcessorService {
+	s.after = after
+	return s
+}
+
+// Do creates a new BulkProcessor and starts it.
+func (s *BulkProcessorService) Do() (*BulkProcessor, error) {
+	p := newBulkProcessor(
+		s.c,
+		s.name,
+		s.numWorkers,
+		s.bulkActions,
+		s.bulkSize,
+		s.flushInterval,
+		s.wantStats,
+		s.backoff,
+		s.before,
+		s.after,
+	)
+	if err := p.Start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// BulkProcessor encapsulates a task that accepts bulk requests and
+// commits them to Elasticsearch, spreading the work across a number
+// of concurrent workers.
+type BulkProcessor struct {
+	c             *Client
+	name          string
+	numWorkers    int
+	bulkActions   int
+	bulkSize      int
+	flushInterval time.Duration
+	wantStats     bool
+	backoff       Backoff
+	before        BulkBeforeFunc
+	after         BulkAfterFunc
+
+	startedMu sync.Mutex
+	started   bool
+
+	requestsMu sync.Mutex
+	requests   []BulkableRequest
+	actions    int
+	size       int
+
+	executionId int64
+	stats       *BulkProcessorStats
+
+	flushTicker *time.Ticker
+	workerWg    sync.WaitGroup
+	flushC      chan []BulkableRequest
+
+	stopC chan struct{}
+}
+
+func newBulkProcessor(
+	client *Client,
+	name string,
+	numWorkers int,
+	bulkActions int,
+	bulkSize int,
+	flushInterval time.Duration,
+	wantStats bool,
+	backoff Backoff,
+	before BulkBeforeFunc,
+	after BulkAfterFunc,
+) *BulkProcessor {
+	return &BulkProcessor{
+		c:             client,
+		name:          name,
+		numWorkers:    numWorkers,
+		bulkActions:   bulkActions,
+		bulkSize:      bulkSize,
+		flushInterval: flushInterval,
+		wantStats:     wantStats,
+		backoff:       backoff,
+		before:        before,
+		after:         after,
+		stats:         &BulkProcessorStats{},
+		stopC:         make(chan struct{}),
+	}
+}
+
+// Start starts the bulk processor's workers. It is called automatically
+// by BulkProcessorService.Do and does not need to be called explicitly.
+func (p *BulkProcessor) Start() error {
+	p.startedMu.Lock()
+	defer p.startedMu.Unlock()
+
+	if p.started {
+		return nil
+	}
+
+	if p.numWorkers < 1 {
+		p.numWorkers = 1
+	}
+
+	p.flushC = make(chan []BulkableRequest, p.numWorkers)
+	for i := 0; i < p.numWorkers; i++ {
+		p.workerWg.Add(1)
+		go p.work()
+	}
+
+	if p.flushInterval > 0 {
+		p.flushTicker = time.NewTicker(p.flushInterval)
+		go p.flusher(p.flushInterval)
+	}
+
+	p.started = true
+
+	return nil
+}
+
+// work is a single worker's main loop. Workers all read from the same
+// flushC channel, so up to numWorkers commits triggered by Add run
+// concurrently instead of piling up behind one another. The periodic
+// flusher does not go through flushC; it commits via Flush.
+func (p *BulkProcessor) work() {
+	defer p.workerWg.Done()
+	for batch := range p.flushC {
+		p.commit(batch)
+	}
+}
+
+// flusher periodically flushes outstanding requests as long as the
+// bulk processor is running.
+func (p *BulkProcessor) flusher(interval time.Duration) {
+	for {
+		select {
+		case <-p.stopC:
+			return
+		case <-p.flushTicker.C:
+			p.Flush()
+		}
+	}
+}
+
+// Stop is an alias for Close.
+func (p *BulkProcessor) Stop() error {
+	return p.Close()
+}
+
+// Close stops the bulk processor, waiting for any outstanding bulk
+// requests to complete before returning.
+func (p *BulkProcessor) Close() error {
+	p.startedMu.Lock()
+	defer p.startedMu.Unlock()
+
+	if !p.started {
+		return nil
+	}
+
+	if p.flushTicker != nil {
+		p.flushTicker.Stop()
+	}
+	close(p.stopC)
+
+	p.Flush()
+
+	close(p.flushC)
+	p.workerWg.Wait()
+
+	p.started = false
+
+	return nil
+}
+
+// Flush manually commits all pending requests and waits for the
+// commit to finish. It is invoked periodically by the flusher when a
+// FlushInterval is configured, and by Close. Batches that reach the
+// BulkActions or BulkSize thresholds in Add are handed to the workers
+// instead and do not go through Flush.
+func (p *BulkProcessor) Flush() error {
+	return p.commit(p.takeBatch())
+}
+
+// takeBatch snapshots and resets the pending batch of requests, holding
+// requestsMu only long enough to do so. It returns nil if there is
+// nothing pending.
+func (p *BulkProcessor) takeBatch() []BulkableRequest {
+	p.requestsMu.Lock()
+	defer p.requestsMu.Unlock()
+
+	if len(p.requests) == 0 {
+		return nil
+	}
+	requests := p.requests
+	p.requests = nil
+	p.actions = 0
+	p.size = 0
+	return requests
+}
+
+// Add adds a single request to commit by the BulkProcessor.
+//
+// The caller is responsible for setting the index, type and ID for
+// index, update and delete requests, either on the request itself
+// or on the client via default values.
+func (p *BulkProcessor) Add(request BulkableRequest) {
+	p.requestsMu.Lock()
+
+	p.requests = append(p.requests, request)
+	p.actions++
+	p.size += estimateSizeInBytes(request)
+
+	var batch []BulkableRequest
+	if p.commitRequired() {
+		batch = p.requests
+		p.requests = nil
+		p.actions = 0
+		p.size = 0
+	}
+
+	p.requestsMu.Unlock()
+
+	if batch != nil {
+		p.flushC <- batch
+	}
+}
+
+// commitRequired returns true if the given number of requests or
+// their estimated size trigger a flush, as configured via BulkActions
+// and BulkSize. It must be called with requestsMu held.
+func (p *BulkProcessor) commitRequired() bool {
+	if p.bulkActions >= 0 && p.actions >= p.bulkActions {
+		return true
+	}
+	if p.bulkSize >= 0 && p.size >= p.bulkSize {
+		return true
+	}
+	return false
+}
+
+// Stats returns the latest bulk processor statistics.
+// Collecting stats must be enabled first, via BulkProcessorService.Stats.
+func (p *BulkProcessor) Stats() BulkProcessorStats {
+	return p.stats.clone()
+}
+
+// estimateSizeInBytes returns the estimated size of a single
+// BulkableRequest that the BulkProcessor uses to decide when to flush.
+func estimateSizeInBytes(r BulkableRequest) int {
+	lines, _ := r.Source()
+	size := 0
+	for _, line := range lines {
+		size += len(line) + 1 // plus newline
+	}
+	return size
+}
+
+// commit commits a set of requests to Elasticsearch, retrying on
+// transient errors according to the BulkProcessor's backoff policy.
+// It is called by a worker goroutine for batches dispatched through
+// flushC, and directly by Flush for a synchronous, caller-requested
+// commit; requestsMu is never held while it runs.
+func (p *BulkProcessor) commit(requests []BulkableRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	id := atomic.AddInt64(&p.executionId, 1)
+
+	if p.before != nil {
+		p.before(id, requests)
+	}
+
+	var res *BulkResponse
+	var err error
+
+	for retry := 0; ; retry++ {
+		res, err = NewBulkService(p.c).Add(requests...).Do()
+		if err == nil || !isTransientError(err) || retry >= maxCommitRetries {
+			break
+		}
+		wait, ok := p.backoff.Next(retry)
+		if !ok {
+			break
+		}
+		time.Sleep(wait)
+	}
+
+	if p.wantStats {
+		p.stats.update(requests, res, err)
+	}
+
+	if p.after != nil {
+		p.after(id, requests, res, err)
+	}
+
+	return err
+}
+
+// -- Stats --
+
+// BulkProcessorStats exposes stats about the bulk requests committed
+// to Elasticsearch by a BulkProcessor.
+type BulkProcessorStats struct {
+	Flushed   int64 // number of non-empty batches committed, whether via Add, Flush or the periodic flusher
+	Committed int64 // number of bulk commits that returned without error
+	Indexed   int64 // number of requests of type index that have been processed
+	Created   int64 // number of requests of type create that have been processed
+	Updated   int64 // number of requests of type update that have been processed
+	Deleted   int64 // number of requests of type delete that have been processed
+	Succeeded int64 // number of items that succeeded
+	Failed    int64 // number of items that failed
+}
+
+func (s *BulkProcessorStats) clone() BulkProcessorStats {
+	return BulkProcessorStats{
+		Flushed:   atomic.LoadInt64(&s.Flushed),
+		Committed: atomic.LoadInt64(&s.Committed),
+		Indexed:   atomic.LoadInt64(&s.Indexed),
+		Created:   atomic.LoadInt64(&s.Created),
+		Updated:   atomic.LoadInt64(&s.Updated),
+		Deleted:   atomic.LoadInt64(&s.Deleted),
+		Succeeded: atomic.LoadInt64(&s.Succeeded),
+		Failed:    atomic.LoadInt64(&s.Failed),
+	}
+}
+
+func (s *BulkProcessorStats) update(requests []BulkableRequest, res *BulkResponse, err error) {
+	atomic.AddInt64(&s.Flushed, 1)
+	if err != nil {
+		atomic.AddInt64(&s.Failed, int64(len(requests)))
+		return
+	}
+	atomic.AddInt64(&s.Committed, 1)
+	if res == nil {
+		return
+	}
+	for _, item := range res.Items {
+		for op, result := range item {
+			if result.Error != nil {
+				atomic.AddInt64(&s.Failed, 1)
+				continue
+			}
+			atomic.AddInt64(&s.Succeeded, 1)
+			switch op {
+			case "index":
+				atomic.AddInt64(&s.Indexed, 1)
+			case "create":
+				atomic.AddInt64(&s.Created, 1)
+			case "update":
+				atomic.AddInt64(&s.Updated, 1)
+			case "delete":
+				atomic.AddInt64(&s.Deleted, 1)
+			}
+		}
+	}
+}
+
+// isTransientError returns true when err looks like a temporary
+// connectivity or server-side problem worth retrying. A 4xx response
+// from Elasticsearch is considered a permanent error and is not retried.
+func isTransientError(err error) bool {
+	if e, ok := err.(*Error); ok {
+		return e.Status >= 500
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package elastic
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeBulkableRequest is a minimal BulkableRequest used to exercise the
+// BulkProcessor's batching logic without depending on a real bulk
+// request implementation.
+type fakeBulkableRequest struct {
+	line string
+}
+
+func (r fakeBulkableRequest) Source() ([]string, error) {
+	return []string{r.line}, nil
+}
+
+func TestBulkProcessorCommitRequiredByActions(t *testing.T) {
+	p := newBulkProcessor(nil, "", 1, 2, -1, 0, false, nil, nil, nil)
+
+	p.actions = 1
+	if p.commitRequired() {
+		t.Fatalf("commitRequired() = true before reaching bulkActions")
+	}
+	p.actions = 2
+	if !p.commitRequired() {
+		t.Fatalf("commitRequired() = false at bulkActions threshold")
+	}
+}
+
+func TestBulkProcessorCommitRequiredBySize(t *testing.T) {
+	p := newBulkProcessor(nil, "", 1, -1, 100, 0, false, nil, nil, nil)
+
+	p.size = 99
+	if p.commitRequired() {
+		t.Fatalf("commitRequired() = true before reaching bulkSize")
+	}
+	p.size = 100
+	if !p.commitRequired() {
+		t.Fatalf("commitRequired() = false at bulkSize threshold")
+	}
+}
+
+func TestBulkProcessorCommitRequiredDisabled(t *testing.T) {
+	p := newBulkProcessor(nil, "", 1, -1, -1, 0, false, nil, nil, nil)
+
+	p.actions = 1 << 20
+	p.size = 1 << 20
+	if p.commitRequired() {
+		t.Fatalf("commitRequired() = true with both thresholds disabled")
+	}
+}
+
+func TestEstimateSizeInBytes(t *testing.T) {
+	got := estimateSizeInBytes(fakeBulkableRequest{line: "abc"})
+	if want := len("abc") + 1; got != want {
+		t.Fatalf("estimateSizeInBytes() = %d, want %d", got, want)
+	}
+}
+
+func TestBulkProcessorTakeBatchResetsState(t *testing.T) {
+	p := newBulkProcessor(nil, "", 1, 1000, 5<<20, 0, false, nil, nil, nil)
+	p.requests = []BulkableRequest{fakeBulkableRequest{line: "a"}, fakeBulkableRequest{line: "b"}}
+	p.actions = 2
+	p.size = 4
+
+	batch := p.takeBatch()
+	if len(batch) != 2 {
+		t.Fatalf("takeBatch() returned %d requests, want 2", len(batch))
+	}
+	if p.requests != nil || p.actions != 0 || p.size != 0 {
+		t.Fatalf("takeBatch() did not reset pending state")
+	}
+	if batch := p.takeBatch(); batch != nil {
+		t.Fatalf("takeBatch() on an empty processor = %v, want nil", batch)
+	}
+}
+
+// TestBulkProcessorAddDispatchesWithoutBlocking verifies that Add hands
+// a full batch off via flushC and returns as soon as the batch is
+// queued, rather than running the commit (and its retries) inline
+// while holding requestsMu, which would serialize every caller of Add
+// behind one another.
+func TestBulkProcessorAddDispatchesWithoutBlocking(t *testing.T) {
+	p := newBulkProcessor(nil, "", 2, 2, -1, 0, false, nil, nil, nil)
+	p.flushC = make(chan []BulkableRequest, p.numWorkers)
+
+	dispatched := make(chan []BulkableRequest, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			dispatched <- <-p.flushC
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		p.Add(fakeBulkableRequest{line: "1"})
+		p.Add(fakeBulkableRequest{line: "2"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Add did not return promptly; it should hand batches off via flushC, not commit them inline")
+	}
+
+	select {
+	case batch := <-dispatched:
+		if len(batch) != 2 {
+			t.Fatalf("dispatched batch has %d requests, want 2", len(batch))
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Add never dispatched a full batch to flushC")
+	}
+}
+
+func TestBulkProcessorStatsUpdateError(t *testing.T) {
+	s := &BulkProcessorStats{}
+	requests := []BulkableRequest{fakeBulkableRequest{line: "a"}, fakeBulkableRequest{line: "b"}}
+
+	s.update(requests, nil, errTest)
+
+	got := s.clone()
+	if got.Flushed != 1 || got.Failed != 2 || got.Committed != 0 {
+		t.Fatalf("update() on error = %+v, want Flushed=1 Failed=2 Committed=0", got)
+	}
+}
+
+func TestBulkProcessorStatsUpdateNilResponse(t *testing.T) {
+	s := &BulkProcessorStats{}
+	requests := []BulkableRequest{fakeBulkableRequest{line: "a"}}
+
+	s.update(requests, nil, nil)
+
+	got := s.clone()
+	if got.Flushed != 1 || got.Committed != 1 || got.Succeeded != 0 {
+		t.Fatalf("update() with nil response = %+v, want Flushed=1 Committed=1 Succeeded=0", got)
+	}
+}
+
+var errTest = errors.New("test error")
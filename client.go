@@ -0,0 +1,262 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientOptionFunc configures a Client during construction, in the
+// functional-options style used throughout this package (see
+// SetURL, SetSniff, SetRetrier, and friends).
+type ClientOptionFunc func(*Client) error
+
+// Client is the entry point to the Elasticsearch REST API. It holds a
+// pool of connections to the nodes of a cluster, a Retrier consulted
+// on failed requests, and the background sniffer/healthchecker that
+// keep the pool up to date.
+type Client struct {
+	c *http.Client
+
+	pool    *connectionPool
+	retrier Retrier
+
+	sniffEnabled    bool
+	snifferInterval time.Duration
+	stopSniffer     chan struct{}
+
+	healthcheckEnabled  bool
+	healthcheckInterval time.Duration
+	healthcheckTimeout  time.Duration
+	stopHealthcheck     chan struct{}
+
+	closeMu sync.Mutex
+	closed  bool
+}
+
+// NewClient creates a new Client and applies the given options. At
+// least one URL must be configured via SetURL. Sniffing and
+// healthchecking are enabled by default; both start in the
+// background once the Client is constructed.
+func NewClient(options ...ClientOptionFunc) (*Client, error) {
+	c := &Client{
+		c:                  http.DefaultClient,
+		retrier:            defaultRetrier(),
+		sniffEnabled:       true,
+		healthcheckEnabled: true,
+		stopSniffer:        make(chan struct{}),
+		stopHealthcheck:    make(chan struct{}),
+	}
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.pool == nil {
+		return nil, fmt.Errorf("elastic: no URL configured, use SetURL")
+	}
+	if c.sniffEnabled {
+		c.startSniffer()
+	}
+	if c.healthcheckEnabled {
+		c.startHealthchecker()
+	}
+	return c, nil
+}
+
+// Close stops the background sniffer and healthchecker goroutines
+// started by NewClient, if enabled. It is safe to call more than
+// once. A Client must not be used for further requests after Close.
+func (c *Client) Close() error {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	close(c.stopSniffer)
+	close(c.stopHealthcheck)
+	c.closed = true
+	return nil
+}
+
+// Response is the result of a single request performed by Client.
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error is returned whenever Elasticsearch responds with a non-2xx
+// status code.
+type Error struct {
+	Status  int
+	Details string
+}
+
+func (e *Error) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("elastic: Error %d (%s)", e.Status, e.Details)
+	}
+	return fmt.Sprintf("elastic: Error %d", e.Status)
+}
+
+// checkResponse returns an error if the HTTP response signals failure.
+func checkResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	return &Error{Status: res.StatusCode}
+}
+
+// cleanPathString trims leading and trailing slashes, so that index
+// and type names can be joined into a path with "," and "/" without
+// doubling up on separators.
+func cleanPathString(s string) string {
+	return strings.Trim(s, "/")
+}
+
+// Request wraps http.Request with the SetBodyJson/SetBodyString
+// helpers the rest of this package constructs requests with via
+// NewRequest.
+type Request http.Request
+
+// SetBodyJson encodes data as JSON and uses it as the request body.
+func (r *Request) SetBodyJson(data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return r.SetBodyString(string(body))
+}
+
+// SetBodyString uses body as the request body.
+func (r *Request) SetBodyString(body string) error {
+	r.Body = ioutil.NopCloser(strings.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return nil
+}
+
+// NewRequest creates a new Request against the next live connection
+// in the pool.
+func (c *Client) NewRequest(method, pathAndQuery string) (*Request, error) {
+	conn, err := c.pickConnection()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(conn.url, "/")+pathAndQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	return (*Request)(req), nil
+}
+
+// PerformRequest executes a request using context.Background(). It
+// exists for backwards compatibility; new code should call
+// PerformRequestC so that the request can be cancelled or deadlined
+// by the caller.
+func (c *Client) PerformRequest(method, path string, params url.Values, body interface{}) (*Response, error) {
+	return c.PerformRequestC(context.Background(), method, path, params, body)
+}
+
+// PerformRequestC issues a request against a live connection picked
+// from the pool in round-robin order. On a connection error or a 5xx
+// response, it marks that connection dead, consults the Client's
+// Retrier for how long to wait, and retries against the next live
+// connection.
+func (c *Client) PerformRequestC(ctx context.Context, method, path string, params url.Values, body interface{}) (*Response, error) {
+	pathAndQuery := path
+	if len(params) > 0 {
+		pathAndQuery += "?" + params.Encode()
+	}
+
+	var data []byte
+	if body != nil {
+		switch b := body.(type) {
+		case string:
+			data = []byte(b)
+		default:
+			encoded, err := json.Marshal(body)
+			if err != nil {
+				return nil, err
+			}
+			data = encoded
+		}
+	}
+
+	var lastErr error
+	for retry := 0; ; retry++ {
+		conn, err := c.pickConnection()
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := c.performRequestOnConnection(ctx, conn, method, pathAndQuery, data)
+		if err == nil {
+			return res, nil
+		}
+
+		if ee, ok := err.(*Error); ok && ee.Status < 500 {
+			return nil, err
+		}
+
+		conn.markDead()
+		lastErr = err
+
+		wait, ok, rerr := c.retrier.Retry(retry, nil, nil, lastErr)
+		if rerr != nil {
+			return nil, rerr
+		}
+		if !ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return nil, lastErr
+}
+
+// performRequestOnConnection performs a single attempt of a request
+// against the given connection, without any retry logic.
+func (c *Client) performRequestOnConnection(ctx context.Context, conn *connection, method, pathAndQuery string, data []byte) (*Response, error) {
+	var bodyReader *bytes.Reader
+	if data != nil {
+		bodyReader = bytes.NewReader(data)
+	} else {
+		bodyReader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimRight(conn.url, "/")+pathAndQuery, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpRes, err := c.c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	respBody, err := ioutil.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(httpRes); err != nil {
+		return nil, err
+	}
+
+	return &Response{StatusCode: httpRes.StatusCode, Body: respBody}, nil
+}
@@ -0,0 +1,15 @@
+package elastic
+
+// SetRetrier sets the Retrier to use for the Client, e.g. consulted by
+// PerformRequest on connection errors and 5xx responses. The default,
+// if not set via this option, is a BackoffRetrier using an
+// ExponentialBackoff with full jitter.
+func SetRetrier(retrier Retrier) ClientOptionFunc {
+	return func(c *Client) error {
+		if retrier == nil {
+			retrier = defaultRetrier()
+		}
+		c.retrier = retrier
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package elastic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestPerformRequestCRespectsContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(okHandler))
+	defer srv.Close()
+
+	c, err := NewClient(SetURL(srv.URL), SetSniff(false), SetHealthcheck(false))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.PerformRequestC(ctx, "GET", "/", nil, nil); err == nil {
+		t.Fatalf("PerformRequestC() with an already-cancelled context returned no error")
+	}
+}
+
+func TestPerformRequestCSucceeds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(okHandler))
+	defer srv.Close()
+
+	c, err := NewClient(SetURL(srv.URL), SetSniff(false), SetHealthcheck(false))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	res, err := c.PerformRequestC(context.Background(), "GET", "/", nil, nil)
+	if err != nil {
+		t.Fatalf("PerformRequestC() error = %v", err)
+	}
+	if res.StatusCode != 200 {
+		t.Fatalf("PerformRequestC() status = %d, want 200", res.StatusCode)
+	}
+}
+
+func TestNewClientRequiresURL(t *testing.T) {
+	if _, err := NewClient(); err == nil {
+		t.Fatalf("NewClient() without SetURL should return an error")
+	}
+}
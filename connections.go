@@ -0,0 +1,366 @@
+package elastic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// This file adds a connection-management subsystem to Client: a pool
+// of connections seeded via SetURL, a sniffer that periodically
+// discovers the cluster's nodes, and a healthchecker that marks
+// failing connections dead and revives them after a backoff. Before
+// issuing each request, PerformRequest consults pickConnection to
+// select a live connection in round-robin order, so that e.g. a
+// ScanCursor.Next call can survive a node going away mid-scroll by
+// retrying against a different one.
+
+const (
+	// defaultSnifferInterval is how often the sniffer refreshes the
+	// list of nodes by default.
+	defaultSnifferInterval = 15 * time.Minute
+
+	// defaultHealthcheckInterval is how often the healthchecker pings
+	// connections by default.
+	defaultHealthcheckInterval = 60 * time.Second
+
+	// defaultHealthcheckTimeout is the timeout used for a single
+	// healthcheck ping by default.
+	defaultHealthcheckTimeout = 1 * time.Second
+)
+
+// SetURL sets the URL(s) the Client will talk to. If sniffing is
+// enabled (the default), these are only used as seed URLs to discover
+// the rest of the cluster; otherwise they are the full set of nodes
+// the Client round-robins over.
+func SetURL(urls ...string) ClientOptionFunc {
+	return func(c *Client) error {
+		if len(urls) == 0 {
+			return fmt.Errorf("elastic: no URLs given")
+		}
+		c.pool = newConnectionPool(urls...)
+		return nil
+	}
+}
+
+// SetSniff enables or disables the sniffer, which periodically calls
+// GET /_nodes/http to discover the nodes of the cluster and keeps the
+// connection pool up to date. Every node that reports an HTTP publish
+// address is added to the pool; nodes are not filtered by role, so
+// e.g. master-only nodes are included. Defaults to true.
+func SetSniff(enabled bool) ClientOptionFunc {
+	return func(c *Client) error {
+		c.sniffEnabled = enabled
+		return nil
+	}
+}
+
+// SetSnifferInterval sets how often the sniffer refreshes the list of
+// nodes. Defaults to 15 minutes.
+func SetSnifferInterval(interval time.Duration) ClientOptionFunc {
+	return func(c *Client) error {
+		c.snifferInterval = interval
+		return nil
+	}
+}
+
+// SetHealthcheck enables or disables the healthchecker, which
+// periodically pings every connection in the pool and marks failing
+// ones dead. Defaults to true.
+func SetHealthcheck(enabled bool) ClientOptionFunc {
+	return func(c *Client) error {
+		c.healthcheckEnabled = enabled
+		return nil
+	}
+}
+
+// SetHealthcheckInterval sets how often the healthchecker pings
+// connections. Defaults to 60 seconds.
+func SetHealthcheckInterval(interval time.Duration) ClientOptionFunc {
+	return func(c *Client) error {
+		c.healthcheckInterval = interval
+		return nil
+	}
+}
+
+// SetHealthcheckTimeout sets the timeout used for a single
+// healthcheck ping. Defaults to 1 second.
+func SetHealthcheckTimeout(timeout time.Duration) ClientOptionFunc {
+	return func(c *Client) error {
+		c.healthcheckTimeout = timeout
+		return nil
+	}
+}
+
+// -- connection pool --
+
+// connection represents a single Elasticsearch node that the Client
+// can send requests to.
+type connection struct {
+	url string
+
+	mu       sync.Mutex
+	dead     bool
+	deadAt   time.Time
+	failures int
+}
+
+func (c *connection) markDead() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dead = true
+	c.deadAt = time.Now()
+	c.failures++
+}
+
+func (c *connection) markLive() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dead = false
+	c.failures = 0
+}
+
+func (c *connection) isDead() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dead
+}
+
+// revivalDelay returns how long to wait before retrying a dead
+// connection, growing exponentially with the number of consecutive
+// failures so that a node that is down for a while isn't hammered
+// with healthchecks.
+func (c *connection) revivalDelay() time.Duration {
+	c.mu.Lock()
+	failures := c.failures
+	c.mu.Unlock()
+
+	wait, _ := NewExponentialBackoff(defaultHealthcheckInterval, 15*time.Minute).Next(failures)
+	return wait
+}
+
+// connectionPool manages a set of connections to the nodes of an
+// Elasticsearch cluster and selects a live one in round-robin order
+// for every outgoing request.
+type connectionPool struct {
+	mu    sync.Mutex
+	conns []*connection
+	next  int
+}
+
+// newConnectionPool creates a connectionPool seeded with the given URLs.
+func newConnectionPool(urls ...string) *connectionPool {
+	p := &connectionPool{}
+	p.setURLs(urls...)
+	return p
+}
+
+// setURLs replaces the pool's connections with one per URL given,
+// preserving the dead/live state of connections that are already
+// known, e.g. across a sniff that rediscovers the same nodes.
+func (p *connectionPool) setURLs(urls ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := make(map[string]*connection, len(p.conns))
+	for _, c := range p.conns {
+		existing[c.url] = c
+	}
+
+	conns := make([]*connection, 0, len(urls))
+	for _, u := range urls {
+		if c, ok := existing[u]; ok {
+			conns = append(conns, c)
+		} else {
+			conns = append(conns, &connection{url: u})
+		}
+	}
+	p.conns = conns
+	p.next = 0
+}
+
+// snapshot returns a copy of the connection slice, safe to range over
+// without holding the pool's lock. Used by the healthchecker, which
+// otherwise runs concurrently with setURLs reassigning p.conns from
+// the sniffer goroutine.
+func (p *connectionPool) snapshot() []*connection {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conns := make([]*connection, len(p.conns))
+	copy(conns, p.conns)
+	return conns
+}
+
+// urls returns the URLs of all connections currently known to the pool.
+func (p *connectionPool) urls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	urls := make([]string, len(p.conns))
+	for i, c := range p.conns {
+		urls[i] = c.url
+	}
+	return urls
+}
+
+// nextConnection returns the next live connection in round-robin
+// order. If every connection is marked dead, it returns the next one
+// in round-robin order anyway so that the Client can attempt to
+// revive it.
+func (p *connectionPool) nextConnection() (*connection, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.conns) == 0 {
+		return nil, fmt.Errorf("elastic: no connections available")
+	}
+
+	for i := 0; i < len(p.conns); i++ {
+		idx := (p.next + i) % len(p.conns)
+		c := p.conns[idx]
+		if !c.isDead() {
+			p.next = idx + 1
+			return c, nil
+		}
+	}
+
+	// All connections are dead: fall back to the next one anyway so
+	// the caller can retry and potentially revive it.
+	c := p.conns[p.next%len(p.conns)]
+	p.next++
+	return c, nil
+}
+
+// pickConnection selects the next live connection in round-robin
+// order. It is consulted by PerformRequest before every request, so
+// that a dead node is skipped in favor of one that is currently
+// responding.
+func (c *Client) pickConnection() (*connection, error) {
+	return c.pool.nextConnection()
+}
+
+// -- sniffer --
+
+// nodesInfoResponse is the relevant subset of the response to
+// GET /_nodes/http.
+type nodesInfoResponse struct {
+	Nodes map[string]struct {
+		HTTP struct {
+			PublishAddress string `json:"publish_address"`
+		} `json:"http"`
+	} `json:"nodes"`
+}
+
+// startSniffer starts a goroutine that runs for the lifetime of the
+// Client, sniffing once right away and then periodically discovering
+// the cluster's nodes and updating the connection pool.
+func (c *Client) startSniffer() {
+	ticker := time.NewTicker(c.snifferIntervalOrDefault())
+	go func() {
+		defer ticker.Stop()
+		c.sniff()
+		for {
+			select {
+			case <-c.stopSniffer:
+				return
+			case <-ticker.C:
+				c.sniff()
+			}
+		}
+	}()
+}
+
+func (c *Client) snifferIntervalOrDefault() time.Duration {
+	if c.snifferInterval > 0 {
+		return c.snifferInterval
+	}
+	return defaultSnifferInterval
+}
+
+// sniff calls GET /_nodes/http on the current pool and, on success,
+// replaces the pool's URLs with the nodes reported back.
+func (c *Client) sniff() {
+	res, err := c.PerformRequest("GET", "/_nodes/http", nil, nil)
+	if err != nil {
+		return
+	}
+
+	var info nodesInfoResponse
+	if err := json.Unmarshal(res.Body, &info); err != nil {
+		return
+	}
+
+	var urls []string
+	for _, node := range info.Nodes {
+		if node.HTTP.PublishAddress == "" {
+			continue
+		}
+		urls = append(urls, "http://"+node.HTTP.PublishAddress)
+	}
+	if len(urls) > 0 {
+		c.pool.setURLs(urls...)
+	}
+}
+
+// -- healthchecker --
+
+// startHealthchecker runs in its own goroutine for the lifetime of
+// the Client, periodically pinging every connection in the pool and
+// marking failing ones dead, reviving them after an exponentially
+// growing delay.
+func (c *Client) startHealthchecker() {
+	ticker := time.NewTicker(c.healthcheckIntervalOrDefault())
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopHealthcheck:
+				return
+			case <-ticker.C:
+				c.healthcheck()
+			}
+		}
+	}()
+}
+
+func (c *Client) healthcheckIntervalOrDefault() time.Duration {
+	if c.healthcheckInterval > 0 {
+		return c.healthcheckInterval
+	}
+	return defaultHealthcheckInterval
+}
+
+func (c *Client) healthcheckTimeoutOrDefault() time.Duration {
+	if c.healthcheckTimeout > 0 {
+		return c.healthcheckTimeout
+	}
+	return defaultHealthcheckTimeout
+}
+
+// healthcheck pings every known connection and updates its dead/live
+// state. Dead connections are only re-pinged once their exponential
+// revival delay has elapsed.
+func (c *Client) healthcheck() {
+	httpClient := &http.Client{Timeout: c.healthcheckTimeoutOrDefault()}
+
+	for _, conn := range c.pool.snapshot() {
+		if conn.isDead() && time.Since(conn.deadAt) < conn.revivalDelay() {
+			continue
+		}
+
+		resp, err := httpClient.Head(conn.url)
+		if err != nil {
+			conn.markDead()
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			conn.markDead()
+			continue
+		}
+		conn.markLive()
+	}
+}
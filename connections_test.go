@@ -0,0 +1,92 @@
+package elastic
+
+import "testing"
+
+func TestConnectionPoolRoundRobin(t *testing.T) {
+	p := newConnectionPool("http://node1:9200", "http://node2:9200")
+
+	seen := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		c, err := p.nextConnection()
+		if err != nil {
+			t.Fatalf("nextConnection: %v", err)
+		}
+		seen[c.url]++
+	}
+
+	if seen["http://node1:9200"] != 2 || seen["http://node2:9200"] != 2 {
+		t.Fatalf("expected round-robin to alternate evenly, got %v", seen)
+	}
+}
+
+func TestConnectionPoolSkipsDeadConnections(t *testing.T) {
+	p := newConnectionPool("http://node1:9200", "http://node2:9200")
+	p.conns[0].markDead()
+
+	for i := 0; i < 4; i++ {
+		c, err := p.nextConnection()
+		if err != nil {
+			t.Fatalf("nextConnection: %v", err)
+		}
+		if c.url != "http://node2:9200" {
+			t.Fatalf("expected to skip dead connection, got %s", c.url)
+		}
+	}
+}
+
+func TestConnectionPoolFallsBackWhenAllDead(t *testing.T) {
+	p := newConnectionPool("http://node1:9200", "http://node2:9200")
+	p.conns[0].markDead()
+	p.conns[1].markDead()
+
+	if _, err := p.nextConnection(); err != nil {
+		t.Fatalf("nextConnection with all dead should still return a connection to retry: %v", err)
+	}
+}
+
+func TestConnectionPoolNoConnections(t *testing.T) {
+	p := &connectionPool{}
+
+	if _, err := p.nextConnection(); err == nil {
+		t.Fatalf("expected error from an empty pool")
+	}
+}
+
+func TestConnectionPoolSetURLsPreservesState(t *testing.T) {
+	p := newConnectionPool("http://node1:9200")
+	p.conns[0].markDead()
+
+	p.setURLs("http://node1:9200", "http://node2:9200")
+
+	if len(p.conns) != 2 {
+		t.Fatalf("expected 2 connections after setURLs, got %d", len(p.conns))
+	}
+	if !p.conns[0].isDead() {
+		t.Fatalf("expected setURLs to preserve the dead state of a rediscovered connection")
+	}
+}
+
+func TestConnectionPoolSnapshotIsIndependentCopy(t *testing.T) {
+	p := newConnectionPool("http://node1:9200")
+
+	snap := p.snapshot()
+	p.setURLs("http://node2:9200")
+
+	if len(snap) != 1 || snap[0].url != "http://node1:9200" {
+		t.Fatalf("snapshot should not observe a later setURLs reassignment, got %v", snap)
+	}
+}
+
+func TestConnectionMarkDeadAndLive(t *testing.T) {
+	c := &connection{url: "http://node1:9200"}
+
+	c.markDead()
+	if !c.isDead() {
+		t.Fatalf("expected connection to be dead after markDead")
+	}
+
+	c.markLive()
+	if c.isDead() {
+		t.Fatalf("expected connection to be live after markLive")
+	}
+}
@@ -1,6 +1,7 @@
 package elastic
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -23,6 +24,7 @@ type MultiTermvectorService struct {
 	realtime   *bool
 	refresh    *bool
 	docs       []*MultiTermvectorItem
+	ids        []string
 }
 
 // NewMultiTermvectorService creates a new MultiTermvectorService.
@@ -56,6 +58,15 @@ func (s *MultiTermvectorService) Type(typ string) *MultiTermvectorService {
 	return s
 }
 
+// Ids is a shortcut for submitting a list of document ids against
+// the fixed Index/Type of the service, instead of an envelope per
+// document via Add. Elasticsearch will apply the same Index/Type to
+// every id in the list.
+func (s *MultiTermvectorService) Ids(ids ...string) *MultiTermvectorService {
+	s.ids = append(s.ids, ids...)
+	return s
+}
+
 //MultiTermvectorItem is a single document to retrive via MultiTermvectorService.
 type MultiTermvectorItem struct {
 	index            string
@@ -65,6 +76,7 @@ type MultiTermvectorItem struct {
 	fieldStatistics  *bool
 	fields           []string
 	perFieldAnalyzer map[string]string
+	filter           *MultiTermvectorFilter
 	offsets          *bool
 	parent           string
 	payloads         *bool
@@ -73,6 +85,8 @@ type MultiTermvectorItem struct {
 	realtime         *bool
 	routing          string
 	termStatistics   *bool
+	version          *int64
+	versionType      string
 }
 
 func NewMultiTermvectorItem() *MultiTermvectorItem {
@@ -123,6 +137,14 @@ func (s *MultiTermvectorItem) PerFieldAnalyzer(perFieldAnalyzer map[string]strin
 	return s
 }
 
+// Filter restricts the terms returned to those matching the given
+// MultiTermvectorFilter, e.g. a minimum/maximum document or term
+// frequency.
+func (s *MultiTermvectorItem) Filter(filter *MultiTermvectorFilter) *MultiTermvectorItem {
+	s.filter = filter
+	return s
+}
+
 // Offsets specifies if term offsets should be returned.
 func (s *MultiTermvectorItem) Offsets(offsets bool) *MultiTermvectorItem {
 	s.offsets = &offsets
@@ -174,6 +196,20 @@ func (s *MultiTermvectorItem) TermStatistics(termStatistics bool) *MultiTermvect
 	return s
 }
 
+// Version is the version number to use for concurrency control
+// against the stored document.
+func (s *MultiTermvectorItem) Version(version int64) *MultiTermvectorItem {
+	s.version = &version
+	return s
+}
+
+// VersionType is the specific version type to use, e.g. "internal",
+// "external", "external_gte", or "force".
+func (s *MultiTermvectorItem) VersionType(versionType string) *MultiTermvectorItem {
+	s.versionType = versionType
+	return s
+}
+
 // Source returns the serialized JSON to be sent to Elasticsearch as
 // part of a MultiTermvector.
 func (s *MultiTermvectorItem) Source() interface{} {
@@ -191,31 +227,31 @@ func (s *MultiTermvectorItem) Source() interface{} {
 		source["fields"] = s.fields
 	}
 	if s.fieldStatistics != nil {
-		source["field_statistics"] = fmt.Sprintf("%v", *s.fieldStatistics)
+		source["field_statistics"] = *s.fieldStatistics
 	}
 	if s.offsets != nil {
-		source["offsets"] = s.offsets
+		source["offsets"] = *s.offsets
 	}
 	if s.parent != "" {
-		source["parant"] = s.parent
+		source["parent"] = s.parent
 	}
 	if s.payloads != nil {
-		source["payloads"] = fmt.Sprintf("%v", *s.payloads)
+		source["payloads"] = *s.payloads
 	}
 	if s.positions != nil {
-		source["positions"] = fmt.Sprintf("%v", *s.positions)
+		source["positions"] = *s.positions
 	}
 	if s.preference != "" {
 		source["preference"] = s.preference
 	}
 	if s.realtime != nil {
-		source["realtime"] = fmt.Sprintf("%v", *s.realtime)
+		source["realtime"] = *s.realtime
 	}
 	if s.routing != "" {
 		source["routing"] = s.routing
 	}
 	if s.termStatistics != nil {
-		source["term_statistics"] = fmt.Sprintf("%v", *s.termStatistics)
+		source["term_statistics"] = *s.termStatistics
 	}
 	if s.doc != nil {
 		source["doc"] = s.doc
@@ -223,12 +259,116 @@ func (s *MultiTermvectorItem) Source() interface{} {
 	if s.perFieldAnalyzer != nil && len(s.perFieldAnalyzer) > 0 {
 		source["per_field_analyzer"] = s.perFieldAnalyzer
 	}
+	if s.filter != nil {
+		source["filter"] = s.filter.Source()
+	}
+	if s.version != nil {
+		source["version"] = *s.version
+	}
+	if s.versionType != "" {
+		source["version_type"] = s.versionType
+	}
 
 	return source
 }
 
+// MultiTermvectorFilter restricts the set of terms a
+// MultiTermvectorItem returns, e.g. to the most representative terms
+// of a document by frequency.
+type MultiTermvectorFilter struct {
+	maxNumTerms   *int
+	minTermFreq   *int
+	maxTermFreq   *int
+	minDocFreq    *int
+	maxDocFreq    *int
+	minWordLength *int
+	maxWordLength *int
+}
+
+// NewMultiTermvectorFilter creates a new MultiTermvectorFilter.
+func NewMultiTermvectorFilter() *MultiTermvectorFilter {
+	return &MultiTermvectorFilter{}
+}
+
+// MaxNumTerms specifies the maximum number of terms to return.
+func (f *MultiTermvectorFilter) MaxNumTerms(maxNumTerms int) *MultiTermvectorFilter {
+	f.maxNumTerms = &maxNumTerms
+	return f
+}
+
+// MinTermFreq ignores words with less than this frequency in the
+// source document.
+func (f *MultiTermvectorFilter) MinTermFreq(minTermFreq int) *MultiTermvectorFilter {
+	f.minTermFreq = &minTermFreq
+	return f
+}
+
+// MaxTermFreq ignores words with more than this frequency in the
+// source document.
+func (f *MultiTermvectorFilter) MaxTermFreq(maxTermFreq int) *MultiTermvectorFilter {
+	f.maxTermFreq = &maxTermFreq
+	return f
+}
+
+// MinDocFreq ignores terms which do not occur in at least this many
+// docs.
+func (f *MultiTermvectorFilter) MinDocFreq(minDocFreq int) *MultiTermvectorFilter {
+	f.minDocFreq = &minDocFreq
+	return f
+}
+
+// MaxDocFreq ignores terms which occur in more than this many docs.
+func (f *MultiTermvectorFilter) MaxDocFreq(maxDocFreq int) *MultiTermvectorFilter {
+	f.maxDocFreq = &maxDocFreq
+	return f
+}
+
+// MinWordLength ignores words shorter than this length.
+func (f *MultiTermvectorFilter) MinWordLength(minWordLength int) *MultiTermvectorFilter {
+	f.minWordLength = &minWordLength
+	return f
+}
+
+// MaxWordLength ignores words longer than this length.
+func (f *MultiTermvectorFilter) MaxWordLength(maxWordLength int) *MultiTermvectorFilter {
+	f.maxWordLength = &maxWordLength
+	return f
+}
+
+// Source returns the serialized JSON for the filter, to be put under
+// the "filter" key of a MultiTermvectorItem.
+func (f *MultiTermvectorFilter) Source() interface{} {
+	source := make(map[string]interface{})
+	if f.maxNumTerms != nil {
+		source["max_num_terms"] = *f.maxNumTerms
+	}
+	if f.minTermFreq != nil {
+		source["min_term_freq"] = *f.minTermFreq
+	}
+	if f.maxTermFreq != nil {
+		source["max_term_freq"] = *f.maxTermFreq
+	}
+	if f.minDocFreq != nil {
+		source["min_doc_freq"] = *f.minDocFreq
+	}
+	if f.maxDocFreq != nil {
+		source["max_doc_freq"] = *f.maxDocFreq
+	}
+	if f.minWordLength != nil {
+		source["min_word_length"] = *f.minWordLength
+	}
+	if f.maxWordLength != nil {
+		source["max_word_length"] = *f.maxWordLength
+	}
+	return source
+}
+
 func (s *MultiTermvectorService) Source() interface{} {
 	source := make(map[string]interface{})
+	if len(s.ids) > 0 {
+		source["ids"] = s.ids
+		return source
+	}
 	docs := make([]interface{}, len(s.docs))
 	for i, doc := range s.docs {
 		docs[i] = doc.Source()
@@ -287,8 +427,17 @@ func (s *MultiTermvectorService) Validate() error {
 	return nil
 }
 
-// Do executes the operation.
+// Do executes the operation using context.Background(). It exists for
+// backwards compatibility; new code should call DoC so that the
+// request can be cancelled or deadlined by the caller.
 func (s *MultiTermvectorService) Do() (*MultiTermvectorResponse, error) {
+	return s.DoC(context.Background())
+}
+
+// DoC executes the operation. The context is passed down to
+// Client.PerformRequestC so that callers can cancel or deadline the
+// underlying HTTP request.
+func (s *MultiTermvectorService) DoC(ctx context.Context) (*MultiTermvectorResponse, error) {
 	// Check pre-conditions
 	if err := s.Validate(); err != nil {
 		return nil, err
@@ -302,7 +451,7 @@ func (s *MultiTermvectorService) Do() (*MultiTermvectorResponse, error) {
 	body := s.Source()
 
 	// Get HTTP response
-	res, err := s.client.PerformRequest("GET", path, params, body)
+	res, err := s.client.PerformRequestC(ctx, "GET", path, params, body)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,84 @@
+package elastic
+
+import "testing"
+
+func TestMultiTermvectorItemSourceIncludesFilter(t *testing.T) {
+	item := NewMultiTermvectorItem().
+		Index("twitter").
+		Type("tweet").
+		Id("1").
+		Filter(NewMultiTermvectorFilter().MaxNumTerms(5).MinTermFreq(2))
+
+	source, ok := item.Source().(map[string]interface{})
+	if !ok {
+		t.Fatalf("Source() = %T, want map[string]interface{}", item.Source())
+	}
+
+	if source["_id"] != "1" || source["_index"] != "twitter" || source["_type"] != "tweet" {
+		t.Fatalf("Source() = %v, missing expected _id/_index/_type", source)
+	}
+
+	filter, ok := source["filter"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Source()[\"filter\"] = %T, want map[string]interface{}", source["filter"])
+	}
+	if filter["max_num_terms"] != 5 || filter["min_term_freq"] != 2 {
+		t.Fatalf("filter source = %v, want max_num_terms=5 min_term_freq=2", filter)
+	}
+	if _, ok := filter["max_term_freq"]; ok {
+		t.Fatalf("filter source = %v, unset max_term_freq should be omitted", filter)
+	}
+}
+
+func TestMultiTermvectorItemSourceBooleans(t *testing.T) {
+	item := NewMultiTermvectorItem().Id("1").Offsets(false).Positions(true)
+
+	source := item.Source().(map[string]interface{})
+	if source["offsets"] != false {
+		t.Fatalf("Source()[\"offsets\"] = %v, want false (not its string form)", source["offsets"])
+	}
+	if source["positions"] != true {
+		t.Fatalf("Source()[\"positions\"] = %v, want true", source["positions"])
+	}
+}
+
+func TestMultiTermvectorServiceSourceWithIds(t *testing.T) {
+	s := NewMultiTermvectorService(nil).Index("twitter").Type("tweet").Ids("1", "2", "3")
+
+	source := s.Source().(map[string]interface{})
+	ids, ok := source["ids"].([]string)
+	if !ok || len(ids) != 3 {
+		t.Fatalf("Source() = %v, want ids=[1 2 3]", source)
+	}
+	if _, ok := source["docs"]; ok {
+		t.Fatalf("Source() = %v, want docs omitted when ids is set", source)
+	}
+}
+
+func TestMultiTermvectorServiceSourceWithDocs(t *testing.T) {
+	s := NewMultiTermvectorService(nil).Add(
+		NewMultiTermvectorItem().Id("1"),
+		NewMultiTermvectorItem().Id("2"),
+	)
+
+	source := s.Source().(map[string]interface{})
+	docs, ok := source["docs"].([]interface{})
+	if !ok || len(docs) != 2 {
+		t.Fatalf("Source() = %v, want 2 docs", source)
+	}
+	if _, ok := source["ids"]; ok {
+		t.Fatalf("Source() = %v, want ids omitted when using Add", source)
+	}
+}
+
+func TestMultiTermvectorServiceBuildURL(t *testing.T) {
+	s := NewMultiTermvectorService(nil).Index("twitter").Type("tweet")
+
+	path, _, err := s.buildURL()
+	if err != nil {
+		t.Fatalf("buildURL() error = %v", err)
+	}
+	if want := "/twitter/tweet/_mtermvectors"; path != want {
+		t.Fatalf("buildURL() = %q, want %q", path, want)
+	}
+}
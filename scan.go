@@ -5,12 +5,11 @@
 package elastic
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"strings"
 )
@@ -28,6 +27,10 @@ var (
 )
 
 // ScanService manages a cursor through documents in Elasticsearch.
+//
+// Deprecated: ScanService relies on search_type=scan, which has been
+// removed from modern versions of Elasticsearch. Use ScrollService
+// instead.
 type ScanService struct {
 	client    *Client
 	indices   []string
@@ -39,6 +42,9 @@ type ScanService struct {
 	debug     bool
 }
 
+// NewScanService creates a new ScanService.
+//
+// Deprecated: use NewScrollService instead.
 func NewScanService(client *Client) *ScanService {
 	builder := &ScanService{
 		client: client,
@@ -115,7 +121,17 @@ func (s *ScanService) Size(size int) *ScanService {
 	return s
 }
 
+// Do executes the scan using context.Background(). It exists for
+// backwards compatibility; new code should call DoC so that the
+// request can be cancelled or deadlined by the caller.
 func (s *ScanService) Do() (*ScanCursor, error) {
+	return s.DoC(context.Background())
+}
+
+// DoC executes the scan through Client.PerformRequestC, so that the
+// request is retried against a different connection in the pool (and
+// can be cancelled via ctx) instead of being pinned to a single node.
+func (s *ScanService) DoC(ctx context.Context) (*ScanCursor, error) {
 	// Build url
 	urls := "/"
 
@@ -154,48 +170,24 @@ func (s *ScanService) Do() (*ScanCursor, error) {
 	if s.size != nil && *s.size > 0 {
 		params.Set("size", fmt.Sprintf("%d", *s.size))
 	}
-	if len(params) > 0 {
-		urls += "?" + params.Encode()
-	}
-
-	// Set up a new request
-	req, err := s.client.NewRequest("POST", urls)
-	if err != nil {
-		return nil, err
-	}
 
-	// Set body
+	// Body
 	body := make(map[string]interface{})
-
-	// Query
 	if s.query != nil {
 		body["query"] = s.query.Source()
 	}
 
-	req.SetBodyJson(body)
-
 	if s.debug {
-		out, _ := httputil.DumpRequestOut((*http.Request)(req), true)
-		fmt.Printf("%s\n", string(out))
+		log.Printf("POST %s %v %v\n", urls, params, body)
 	}
 
-	// Get response
-	res, err := s.client.c.Do((*http.Request)(req))
+	res, err := s.client.PerformRequestC(ctx, "POST", urls, params, body)
 	if err != nil {
 		return nil, err
 	}
-	if err := checkResponse(res); err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if s.debug {
-		out, _ := httputil.DumpResponse(res, true)
-		fmt.Printf("%s\n", string(out))
-	}
 
 	searchResult := new(SearchResult)
-	if err := json.NewDecoder(res.Body).Decode(searchResult); err != nil {
+	if err := json.Unmarshal(res.Body, searchResult); err != nil {
 		return nil, err
 	}
 
@@ -238,7 +230,9 @@ func (c *ScanCursor) TotalHits() int64 {
 }
 
 // Next returns the next search result or nil when all
-// documents have been scanned.
+// documents have been scanned. It runs with context.Background() and
+// exists for backwards compatibility; new code should call NextC so
+// that the request can be cancelled or deadlined by the caller.
 //
 // Usage:
 //
@@ -255,6 +249,14 @@ func (c *ScanCursor) TotalHits() int64 {
 //   }
 //
 func (c *ScanCursor) Next() (*SearchResult, error) {
+	return c.NextC(context.Background())
+}
+
+// NextC returns the next search result or nil when all documents have
+// been scanned. It goes through Client.PerformRequestC like every
+// other request, so a node that has gone away mid-scroll is retried
+// against a different connection in the pool instead of hanging.
+func (c *ScanCursor) NextC(ctx context.Context) (*SearchResult, error) {
 	if c.currentPage > 0 {
 		if c.Results.Hits == nil || len(c.Results.Hits.Hits) == 0 || c.Results.Hits.TotalHits == 0 {
 			return nil, EOS
@@ -264,9 +266,6 @@ func (c *ScanCursor) Next() (*SearchResult, error) {
 		return nil, ErrNoScrollId
 	}
 
-	// Build url
-	urls := "/_search/scroll"
-
 	// Parameters
 	params := make(url.Values)
 	if c.pretty {
@@ -277,38 +276,21 @@ func (c *ScanCursor) Next() (*SearchResult, error) {
 	} else {
 		params.Set("scroll", defaultKeepAlive)
 	}
-	urls += "?" + params.Encode()
 
-	// Set up a new request
-	req, err := c.client.NewRequest("POST", urls)
-	if err != nil {
-		return nil, err
+	body := map[string]interface{}{
+		"scroll_id": c.Results.ScrollId,
 	}
 
-	// Set body
-	req.SetBodyString(c.Results.ScrollId)
-
 	if c.debug {
-		out, _ := httputil.DumpRequestOut((*http.Request)(req), true)
-		log.Printf("%s\n", string(out))
+		log.Printf("POST /_search/scroll %v %v\n", params, body)
 	}
 
-	// Get response
-	res, err := c.client.c.Do((*http.Request)(req))
+	res, err := c.client.PerformRequestC(ctx, "POST", "/_search/scroll", params, body)
 	if err != nil {
 		return nil, err
 	}
-	if err := checkResponse(res); err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if c.debug {
-		out, _ := httputil.DumpResponse(res, true)
-		log.Printf("%s\n", string(out))
-	}
 
-	if err := json.NewDecoder(res.Body).Decode(c.Results); err != nil {
+	if err := json.Unmarshal(res.Body, c.Results); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,480 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// ScrollService manages a cursor through documents in Elasticsearch
+// using the regular _search?scroll=... endpoint. Unlike ScanService,
+// it does not rely on search_type=scan, which has been removed from
+// modern versions of Elasticsearch, and it supports context-aware
+// cancellation and sliced scrolling.
+//
+// See https://www.elastic.co/guide/en/elasticsearch/reference/current/search-request-scroll.html
+// for documentation.
+type ScrollService struct {
+	client *Client
+
+	indices []string
+	types   []string
+
+	query             Query
+	sorters           []Sorter
+	fetchSourceContext *FetchSourceContext
+	size              *int
+	keepAlive         string
+	routing           string
+
+	sliceId  *int
+	sliceMax *int
+
+	pretty bool
+}
+
+// NewScrollService creates a new ScrollService.
+func NewScrollService(client *Client) *ScrollService {
+	return &ScrollService{
+		client:    client,
+		keepAlive: defaultKeepAlive,
+	}
+}
+
+// Index sets the index (or indices) to scroll over.
+func (s *ScrollService) Index(indices ...string) *ScrollService {
+	s.indices = append(s.indices, indices...)
+	return s
+}
+
+// Type sets the type (or types) to scroll over.
+func (s *ScrollService) Type(types ...string) *ScrollService {
+	s.types = append(s.types, types...)
+	return s
+}
+
+// Query sets the query to scroll for. Defaults to a match_all query
+// if none is given.
+func (s *ScrollService) Query(query Query) *ScrollService {
+	s.query = query
+	return s
+}
+
+// Sort adds one or more sorters to the scroll request.
+func (s *ScrollService) Sort(sorters ...Sorter) *ScrollService {
+	s.sorters = append(s.sorters, sorters...)
+	return s
+}
+
+// FetchSource indicates whether the response should contain the
+// stored _source for every hit.
+func (s *ScrollService) FetchSource(fetchSourceContext *FetchSourceContext) *ScrollService {
+	s.fetchSourceContext = fetchSourceContext
+	return s
+}
+
+// Size sets the number of hits returned per page.
+func (s *ScrollService) Size(size int) *ScrollService {
+	s.size = &size
+	return s
+}
+
+// Scroll sets the maximum time the scroll context will be kept alive
+// between two calls to Do (e.g. "5m" for 5 minutes). This is an alias
+// of KeepAlive for users coming from ScanService.
+func (s *ScrollService) Scroll(keepAlive string) *ScrollService {
+	s.keepAlive = keepAlive
+	return s
+}
+
+// KeepAlive sets the maximum time the scroll context will be kept
+// alive between two calls to Do (e.g. "5m" for 5 minutes).
+func (s *ScrollService) KeepAlive(keepAlive string) *ScrollService {
+	s.keepAlive = keepAlive
+	return s
+}
+
+// Routing sets a specific routing value.
+func (s *ScrollService) Routing(routing string) *ScrollService {
+	s.routing = routing
+	return s
+}
+
+// Slice splits the scroll into max slices and makes this request
+// responsible for the slice with the given id, so that multiple
+// scrolls can be consumed concurrently. See
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-request-scroll.html#sliced-scroll
+// for details.
+func (s *ScrollService) Slice(id, max int) *ScrollService {
+	s.sliceId = &id
+	s.sliceMax = &max
+	return s
+}
+
+// Pretty indicates that the JSON response be indented and human readable.
+func (s *ScrollService) Pretty(pretty bool) *ScrollService {
+	s.pretty = pretty
+	return s
+}
+
+// buildURL builds the URL for starting the scroll.
+func (s *ScrollService) buildURL() (string, url.Values, error) {
+	var path string
+
+	indexPart := make([]string, 0)
+	for _, index := range s.indices {
+		indexPart = append(indexPart, cleanPathString(index))
+	}
+	typesPart := make([]string, 0)
+	for _, typ := range s.types {
+		typesPart = append(typesPart, cleanPathString(typ))
+	}
+
+	if len(indexPart) > 0 {
+		path += "/" + strings.Join(indexPart, ",")
+	}
+	if len(typesPart) > 0 {
+		path += "/" + strings.Join(typesPart, ",")
+	}
+	path += "/_search"
+
+	params := url.Values{}
+	if s.pretty {
+		params.Set("pretty", "1")
+	}
+	if s.keepAlive != "" {
+		params.Set("scroll", s.keepAlive)
+	} else {
+		params.Set("scroll", defaultKeepAlive)
+	}
+	if s.routing != "" {
+		params.Set("routing", s.routing)
+	}
+	return path, params, nil
+}
+
+// body builds the request body for starting the scroll.
+func (s *ScrollService) body() interface{} {
+	body := make(map[string]interface{})
+
+	query := s.query
+	if query == nil {
+		query = NewMatchAllQuery()
+	}
+	body["query"] = query.Source()
+
+	if len(s.sorters) > 0 {
+		sorts := make([]interface{}, len(s.sorters))
+		for i, sorter := range s.sorters {
+			sorts[i] = sorter.Source()
+		}
+		body["sort"] = sorts
+	}
+	if s.size != nil {
+		body["size"] = *s.size
+	}
+	if s.fetchSourceContext != nil {
+		body["_source"] = s.fetchSourceContext.Source()
+	}
+	if s.sliceId != nil && s.sliceMax != nil {
+		body["slice"] = map[string]interface{}{
+			"id":  *s.sliceId,
+			"max": *s.sliceMax,
+		}
+	}
+	return body
+}
+
+// Do starts the scroll and returns a ScrollCursor that subsequent
+// pages can be read from via Cursor.Do(ctx) until io.EOF.
+func (s *ScrollService) Do(ctx context.Context) (*ScrollCursor, error) {
+	path, params, err := s.buildURL()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.PerformRequestC(ctx, "POST", path, params, s.body())
+	if err != nil {
+		return nil, err
+	}
+
+	searchResult := new(SearchResult)
+	if err := json.Unmarshal(res.Body, searchResult); err != nil {
+		return nil, err
+	}
+
+	return newScrollCursor(s.client, s.keepAlive, s.pretty, searchResult), nil
+}
+
+// ScrollCursor represents a single page of results from a scroll,
+// as well as the scroll_id required to fetch the next one.
+type ScrollCursor struct {
+	client    *Client
+	keepAlive string
+	pretty    bool
+
+	currentPage int
+	result      *SearchResult
+}
+
+func newScrollCursor(client *Client, keepAlive string, pretty bool, result *SearchResult) *ScrollCursor {
+	return &ScrollCursor{
+		client:    client,
+		keepAlive: keepAlive,
+		pretty:    pretty,
+		result:    result,
+	}
+}
+
+// TotalHits returns the number of hits the cursor will iterate through.
+func (c *ScrollCursor) TotalHits() int64 {
+	if c.result == nil || c.result.Hits == nil {
+		return 0
+	}
+	return c.result.Hits.TotalHits
+}
+
+// Do returns the next page of results, or io.EOF once the scroll is
+// exhausted. The very first call returns the page obtained from
+// ScrollService.Do; subsequent calls fetch further pages via
+// _search/scroll.
+func (c *ScrollCursor) Do(ctx context.Context) (*SearchResult, error) {
+	if c.currentPage == 0 {
+		c.currentPage++
+		if c.result.Hits == nil || len(c.result.Hits.Hits) == 0 {
+			return nil, io.EOF
+		}
+		return c.result, nil
+	}
+
+	if c.result.ScrollId == "" {
+		return nil, ErrNoScrollId
+	}
+
+	params := url.Values{}
+	if c.pretty {
+		params.Set("pretty", "1")
+	}
+	if c.keepAlive != "" {
+		params.Set("scroll", c.keepAlive)
+	} else {
+		params.Set("scroll", defaultKeepAlive)
+	}
+
+	body := map[string]interface{}{
+		"scroll_id": c.result.ScrollId,
+	}
+	res, err := c.client.PerformRequestC(ctx, "POST", "/_search/scroll", params, body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(SearchResult)
+	if err := json.Unmarshal(res.Body, result); err != nil {
+		return nil, err
+	}
+	c.result = result
+	c.currentPage++
+
+	if result.Hits == nil || len(result.Hits.Hits) == 0 {
+		return nil, io.EOF
+	}
+	return result, nil
+}
+
+// Clear releases the scroll context on the server via
+// DELETE /_search/scroll, instead of letting it linger until
+// KeepAlive expires. Callers should call Clear once they stop
+// reading from the cursor, typically in a defer.
+func (c *ScrollCursor) Clear(ctx context.Context) error {
+	if c.result == nil || c.result.ScrollId == "" {
+		return nil
+	}
+	body := map[string]interface{}{
+		"scroll_id": []string{c.result.ScrollId},
+	}
+	_, err := c.client.PerformRequestC(ctx, "DELETE", "/_search/scroll", url.Values{}, body)
+	return err
+}
+
+// -- SearchAfterService --
+
+// SearchAfterService paginates through search results using the
+// search_after parameter, which allows deep pagination without
+// keeping a scroll context alive on the server. It requires a stable
+// Sort, typically including _id or another tiebreaker field, so that
+// every page has a well-defined successor.
+//
+// See https://www.elastic.co/guide/en/elasticsearch/reference/current/search-request-search-after.html
+// for documentation.
+type SearchAfterService struct {
+	client *Client
+
+	indices []string
+	types   []string
+
+	query              Query
+	sorters            []Sorter
+	searchAfter        []interface{}
+	fetchSourceContext *FetchSourceContext
+	size               *int
+	routing            string
+	pretty             bool
+}
+
+// NewSearchAfterService creates a new SearchAfterService.
+func NewSearchAfterService(client *Client) *SearchAfterService {
+	return &SearchAfterService{client: client}
+}
+
+// Index sets the index (or indices) to search.
+func (s *SearchAfterService) Index(indices ...string) *SearchAfterService {
+	s.indices = append(s.indices, indices...)
+	return s
+}
+
+// Type sets the type (or types) to search.
+func (s *SearchAfterService) Type(types ...string) *SearchAfterService {
+	s.types = append(s.types, types...)
+	return s
+}
+
+// Query sets the query to run. Defaults to a match_all query if none
+// is given.
+func (s *SearchAfterService) Query(query Query) *SearchAfterService {
+	s.query = query
+	return s
+}
+
+// Sort adds one or more sorters. At least one sorter that produces a
+// unique value per document (e.g. _id) is required for search_after
+// to paginate correctly.
+func (s *SearchAfterService) Sort(sorters ...Sorter) *SearchAfterService {
+	s.sorters = append(s.sorters, sorters...)
+	return s
+}
+
+// SearchAfter sets the sort values of the last hit of the previous
+// page. Leave unset to fetch the first page.
+func (s *SearchAfterService) SearchAfter(values ...interface{}) *SearchAfterService {
+	s.searchAfter = values
+	return s
+}
+
+// FetchSource indicates whether the response should contain the
+// stored _source for every hit.
+func (s *SearchAfterService) FetchSource(fetchSourceContext *FetchSourceContext) *SearchAfterService {
+	s.fetchSourceContext = fetchSourceContext
+	return s
+}
+
+// Size sets the number of hits returned per page.
+func (s *SearchAfterService) Size(size int) *SearchAfterService {
+	s.size = &size
+	return s
+}
+
+// Routing sets a specific routing value.
+func (s *SearchAfterService) Routing(routing string) *SearchAfterService {
+	s.routing = routing
+	return s
+}
+
+// Pretty indicates that the JSON response be indented and human readable.
+func (s *SearchAfterService) Pretty(pretty bool) *SearchAfterService {
+	s.pretty = pretty
+	return s
+}
+
+// Validate checks if the operation is valid.
+func (s *SearchAfterService) Validate() error {
+	var invalid []string
+	if len(s.sorters) == 0 {
+		invalid = append(invalid, "Sort")
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("missing required fields: %v", invalid)
+	}
+	return nil
+}
+
+func (s *SearchAfterService) buildURL() string {
+	indexPart := make([]string, 0)
+	for _, index := range s.indices {
+		indexPart = append(indexPart, cleanPathString(index))
+	}
+	typesPart := make([]string, 0)
+	for _, typ := range s.types {
+		typesPart = append(typesPart, cleanPathString(typ))
+	}
+
+	var path string
+	if len(indexPart) > 0 {
+		path += "/" + strings.Join(indexPart, ",")
+	}
+	if len(typesPart) > 0 {
+		path += "/" + strings.Join(typesPart, ",")
+	}
+	path += "/_search"
+	return path
+}
+
+func (s *SearchAfterService) body() interface{} {
+	body := make(map[string]interface{})
+
+	query := s.query
+	if query == nil {
+		query = NewMatchAllQuery()
+	}
+	body["query"] = query.Source()
+
+	sorts := make([]interface{}, len(s.sorters))
+	for i, sorter := range s.sorters {
+		sorts[i] = sorter.Source()
+	}
+	body["sort"] = sorts
+
+	if len(s.searchAfter) > 0 {
+		body["search_after"] = s.searchAfter
+	}
+	if s.size != nil {
+		body["size"] = *s.size
+	}
+	if s.fetchSourceContext != nil {
+		body["_source"] = s.fetchSourceContext.Source()
+	}
+	return body
+}
+
+// Do executes the request and returns a single page of results. The
+// caller is expected to extract the sort values of the last hit and
+// pass them to SearchAfter on the next call in order to fetch the
+// following page.
+func (s *SearchAfterService) Do(ctx context.Context) (*SearchResult, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	path := s.buildURL()
+	params := url.Values{}
+	if s.pretty {
+		params.Set("pretty", "1")
+	}
+	if s.routing != "" {
+		params.Set("routing", s.routing)
+	}
+
+	res, err := s.client.PerformRequestC(ctx, "POST", path, params, s.body())
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(SearchResult)
+	if err := json.Unmarshal(res.Body, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
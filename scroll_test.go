@@ -0,0 +1,57 @@
+package elastic
+
+import "testing"
+
+func TestScrollServiceBuildURL(t *testing.T) {
+	s := NewScrollService(nil).Index("twitter").Type("tweet")
+
+	path, params, err := s.buildURL()
+	if err != nil {
+		t.Fatalf("buildURL() error = %v", err)
+	}
+	if want := "/twitter/tweet/_search"; path != want {
+		t.Fatalf("buildURL() path = %q, want %q", path, want)
+	}
+	if got := params.Get("scroll"); got != defaultKeepAlive {
+		t.Fatalf("buildURL() scroll param = %q, want default %q", got, defaultKeepAlive)
+	}
+}
+
+func TestScrollServiceBuildURLWithKeepAlive(t *testing.T) {
+	s := NewScrollService(nil).KeepAlive("1m")
+
+	_, params, err := s.buildURL()
+	if err != nil {
+		t.Fatalf("buildURL() error = %v", err)
+	}
+	if got := params.Get("scroll"); got != "1m" {
+		t.Fatalf("buildURL() scroll param = %q, want %q", got, "1m")
+	}
+}
+
+func TestScrollServiceBuildURLNoIndexOrType(t *testing.T) {
+	s := NewScrollService(nil)
+
+	path, _, err := s.buildURL()
+	if err != nil {
+		t.Fatalf("buildURL() error = %v", err)
+	}
+	if want := "/_search"; path != want {
+		t.Fatalf("buildURL() path = %q, want %q", path, want)
+	}
+}
+
+func TestSearchAfterServiceValidateRequiresSort(t *testing.T) {
+	s := NewSearchAfterService(nil)
+	if err := s.Validate(); err == nil {
+		t.Fatalf("Validate() without Sort should fail")
+	}
+}
+
+func TestSearchAfterServiceBuildURL(t *testing.T) {
+	s := NewSearchAfterService(nil).Index("twitter").Type("tweet")
+
+	if want, got := "/twitter/tweet/_search", s.buildURL(); got != want {
+		t.Fatalf("buildURL() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,361 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/olivere/elastic/uritemplates"
+)
+
+// TermvectorsService returns information and statistics on terms in the
+// fields of a particular document, for the common case of a single
+// document. For batching multiple documents into one round-trip, use
+// MultiTermvectorService instead.
+//
+// See https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-termvectors.html
+// for documentation.
+type TermvectorsService struct {
+	client *Client
+
+	pretty bool
+	index  string
+	typ    string
+	id     string
+	doc    interface{}
+
+	fieldStatistics  *bool
+	fields           []string
+	perFieldAnalyzer map[string]string
+	filter           *MultiTermvectorFilter
+	offsets          *bool
+	parent           string
+	payloads         *bool
+	positions        *bool
+	preference       string
+	realtime         *bool
+	routing          string
+	termStatistics   *bool
+	version          *int64
+	versionType      string
+}
+
+// NewTermvectorsService creates a new TermvectorsService.
+func NewTermvectorsService(client *Client) *TermvectorsService {
+	return &TermvectorsService{
+		client: client,
+	}
+}
+
+// Pretty indicates that the JSON response be indented and human readable.
+func (s *TermvectorsService) Pretty(pretty bool) *TermvectorsService {
+	s.pretty = pretty
+	return s
+}
+
+// Index in which the document resides.
+func (s *TermvectorsService) Index(index string) *TermvectorsService {
+	s.index = index
+	return s
+}
+
+// Type of the document.
+func (s *TermvectorsService) Type(typ string) *TermvectorsService {
+	s.typ = typ
+	return s
+}
+
+// Id of the document to fetch term vectors for. Leave unset and use
+// Doc for an artificial document that has not been indexed.
+func (s *TermvectorsService) Id(id string) *TermvectorsService {
+	s.id = id
+	return s
+}
+
+// Doc is an artificial document to analyze, instead of one already
+// stored in the index.
+func (s *TermvectorsService) Doc(doc interface{}) *TermvectorsService {
+	s.doc = doc
+	return s
+}
+
+// FieldStatistics specifies if document count, sum of document frequencies
+// and sum of total term frequencies should be returned.
+func (s *TermvectorsService) FieldStatistics(fieldStatistics bool) *TermvectorsService {
+	s.fieldStatistics = &fieldStatistics
+	return s
+}
+
+// Fields a list of fields to return.
+func (s *TermvectorsService) Fields(fields ...string) *TermvectorsService {
+	s.fields = append(s.fields, fields...)
+	return s
+}
+
+// PerFieldAnalyzer allows to specify a different analyzer than the one
+// at the field.
+func (s *TermvectorsService) PerFieldAnalyzer(perFieldAnalyzer map[string]string) *TermvectorsService {
+	s.perFieldAnalyzer = perFieldAnalyzer
+	return s
+}
+
+// Filter restricts the terms returned to those matching the given
+// MultiTermvectorFilter, e.g. a minimum/maximum document or term
+// frequency.
+func (s *TermvectorsService) Filter(filter *MultiTermvectorFilter) *TermvectorsService {
+	s.filter = filter
+	return s
+}
+
+// Offsets specifies if term offsets should be returned.
+func (s *TermvectorsService) Offsets(offsets bool) *TermvectorsService {
+	s.offsets = &offsets
+	return s
+}
+
+// Parent id of the document.
+func (s *TermvectorsService) Parent(parent string) *TermvectorsService {
+	s.parent = parent
+	return s
+}
+
+// Payloads specifies if term payloads should be returned.
+func (s *TermvectorsService) Payloads(payloads bool) *TermvectorsService {
+	s.payloads = &payloads
+	return s
+}
+
+// Positions specifies if term positions should be returned.
+func (s *TermvectorsService) Positions(positions bool) *TermvectorsService {
+	s.positions = &positions
+	return s
+}
+
+// Preference specify the node or shard the operation
+// should be performed on (default: random).
+func (s *TermvectorsService) Preference(preference string) *TermvectorsService {
+	s.preference = preference
+	return s
+}
+
+// Realtime specifies if request is real-time as opposed to
+// near-real-time (default: true).
+func (s *TermvectorsService) Realtime(realtime bool) *TermvectorsService {
+	s.realtime = &realtime
+	return s
+}
+
+// Routing is a specific routing value.
+func (s *TermvectorsService) Routing(routing string) *TermvectorsService {
+	s.routing = routing
+	return s
+}
+
+// TermStatistics specifies if total term frequency and document frequency
+// should be returned.
+func (s *TermvectorsService) TermStatistics(termStatistics bool) *TermvectorsService {
+	s.termStatistics = &termStatistics
+	return s
+}
+
+// Version is the version number to use for concurrency control
+// against the stored document.
+func (s *TermvectorsService) Version(version int64) *TermvectorsService {
+	s.version = &version
+	return s
+}
+
+// VersionType is the specific version type to use, e.g. "internal",
+// "external", "external_gte", or "force".
+func (s *TermvectorsService) VersionType(versionType string) *TermvectorsService {
+	s.versionType = versionType
+	return s
+}
+
+// Source returns the serialized JSON to be sent to Elasticsearch as
+// the body of the request.
+func (s *TermvectorsService) Source() interface{} {
+	source := make(map[string]interface{})
+
+	if s.fieldStatistics != nil {
+		source["field_statistics"] = *s.fieldStatistics
+	}
+	if s.fields != nil {
+		source["fields"] = s.fields
+	}
+	if s.offsets != nil {
+		source["offsets"] = *s.offsets
+	}
+	if s.parent != "" {
+		source["parent"] = s.parent
+	}
+	if s.payloads != nil {
+		source["payloads"] = *s.payloads
+	}
+	if s.positions != nil {
+		source["positions"] = *s.positions
+	}
+	if s.preference != "" {
+		source["preference"] = s.preference
+	}
+	if s.realtime != nil {
+		source["realtime"] = *s.realtime
+	}
+	if s.routing != "" {
+		source["routing"] = s.routing
+	}
+	if s.termStatistics != nil {
+		source["term_statistics"] = *s.termStatistics
+	}
+	if s.doc != nil {
+		source["doc"] = s.doc
+	}
+	if s.perFieldAnalyzer != nil && len(s.perFieldAnalyzer) > 0 {
+		source["per_field_analyzer"] = s.perFieldAnalyzer
+	}
+	if s.filter != nil {
+		source["filter"] = s.filter.Source()
+	}
+	if s.version != nil {
+		source["version"] = *s.version
+	}
+	if s.versionType != "" {
+		source["version_type"] = s.versionType
+	}
+
+	return source
+}
+
+// buildURL builds the URL for the operation.
+func (s *TermvectorsService) buildURL() (string, url.Values, error) {
+	var path string
+	var err error
+
+	if s.id != "" {
+		path, err = uritemplates.Expand("/{index}/{type}/{id}/_termvectors", map[string]string{
+			"index": s.index,
+			"type":  s.typ,
+			"id":    s.id,
+		})
+	} else {
+		path, err = uritemplates.Expand("/{index}/{type}/_termvectors", map[string]string{
+			"index": s.index,
+			"type":  s.typ,
+		})
+	}
+	if err != nil {
+		return "", url.Values{}, err
+	}
+
+	params := url.Values{}
+	if s.pretty {
+		params.Set("pretty", "1")
+	}
+	if s.preference != "" {
+		params.Set("preference", s.preference)
+	}
+	if s.realtime != nil {
+		params.Set("realtime", fmt.Sprintf("%v", *s.realtime))
+	}
+	return path, params, nil
+}
+
+// Validate checks if the operation is valid.
+func (s *TermvectorsService) Validate() error {
+	var invalid []string
+	if s.index == "" {
+		invalid = append(invalid, "Index")
+	}
+	if s.typ == "" {
+		invalid = append(invalid, "Type")
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("missing required fields: %v", invalid)
+	}
+	return nil
+}
+
+// Do executes the operation using context.Background(). It exists for
+// backwards compatibility; new code should call DoC so that the
+// request can be cancelled or deadlined by the caller.
+func (s *TermvectorsService) Do() (*TermvectorsResponse, error) {
+	return s.DoC(context.Background())
+}
+
+// DoC executes the operation. The context is passed down to
+// Client.PerformRequestC so that callers can cancel or deadline the
+// underlying HTTP request.
+func (s *TermvectorsService) DoC(ctx context.Context) (*TermvectorsResponse, error) {
+	// Check pre-conditions
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Get URL for request
+	path, params, err := s.buildURL()
+	if err != nil {
+		return nil, err
+	}
+	body := s.Source()
+
+	// Get HTTP response
+	res, err := s.client.PerformRequestC(ctx, "GET", path, params, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return operation response
+	ret := new(TermvectorsResponse)
+	if err := json.Unmarshal(res.Body, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// TermvectorsResponse is the response of TermvectorsService.Do, as well
+// as the type of each entry in MultiTermvectorResponse.Docs.
+type TermvectorsResponse struct {
+	Index       string                            `json:"_index,omitempty"`
+	Type        string                            `json:"_type,omitempty"`
+	Id          string                            `json:"_id,omitempty"`
+	Version     int                               `json:"_version,omitempty"`
+	Found       bool                              `json:"found"`
+	Took        int64                             `json:"took"`
+	TermVectors map[string]TermvectorsFieldVector `json:"term_vectors,omitempty"`
+}
+
+// TermvectorsFieldVector holds the per-field statistics and terms
+// returned for a single field of a TermvectorsResponse.
+type TermvectorsFieldVector struct {
+	FieldStatistics TermvectorsFieldStatistics `json:"field_statistics"`
+	Terms           map[string]TermvectorsTerm `json:"terms"`
+}
+
+// TermvectorsFieldStatistics holds the document/term frequency
+// statistics for a single field, as requested via
+// TermvectorsService.FieldStatistics.
+type TermvectorsFieldStatistics struct {
+	SumDocFreq int64 `json:"sum_doc_freq"`
+	DocCount   int   `json:"doc_count"`
+	SumTtf     int64 `json:"sum_ttf"`
+}
+
+// TermvectorsTerm holds the statistics and tokens for a single term of
+// a field, as requested via TermvectorsService.TermStatistics,
+// Offsets, Payloads, and Positions.
+type TermvectorsTerm struct {
+	DocFreq  int                `json:"doc_freq,omitempty"`
+	TermFreq int                `json:"term_freq"`
+	Ttf      int64              `json:"ttf,omitempty"`
+	Tokens   []TermvectorsToken `json:"tokens,omitempty"`
+}
+
+// TermvectorsToken holds the position, offsets, and payload of a
+// single occurrence of a term.
+type TermvectorsToken struct {
+	Position    int    `json:"position"`
+	StartOffset int    `json:"start_offset,omitempty"`
+	EndOffset   int    `json:"end_offset,omitempty"`
+	Payload     string `json:"payload,omitempty"`
+}
@@ -0,0 +1,56 @@
+package elastic
+
+import "testing"
+
+func TestTermvectorsServiceSource(t *testing.T) {
+	s := NewTermvectorsService(nil).
+		Version(3).
+		VersionType("external").
+		Filter(NewMultiTermvectorFilter().MinWordLength(4))
+
+	source := s.Source().(map[string]interface{})
+	if source["version"] != int64(3) || source["version_type"] != "external" {
+		t.Fatalf("Source() = %v, want version=3 version_type=external", source)
+	}
+
+	filter, ok := source["filter"].(map[string]interface{})
+	if !ok || filter["min_word_length"] != 4 {
+		t.Fatalf("Source()[\"filter\"] = %v, want min_word_length=4", source["filter"])
+	}
+}
+
+func TestTermvectorsServiceBuildURLWithId(t *testing.T) {
+	s := NewTermvectorsService(nil).Index("twitter").Type("tweet").Id("1")
+
+	path, _, err := s.buildURL()
+	if err != nil {
+		t.Fatalf("buildURL() error = %v", err)
+	}
+	if want := "/twitter/tweet/1/_termvectors"; path != want {
+		t.Fatalf("buildURL() = %q, want %q", path, want)
+	}
+}
+
+func TestTermvectorsServiceBuildURLWithoutId(t *testing.T) {
+	s := NewTermvectorsService(nil).Index("twitter").Type("tweet")
+
+	path, _, err := s.buildURL()
+	if err != nil {
+		t.Fatalf("buildURL() error = %v", err)
+	}
+	if want := "/twitter/tweet/_termvectors"; path != want {
+		t.Fatalf("buildURL() = %q, want %q", path, want)
+	}
+}
+
+func TestTermvectorsServiceValidate(t *testing.T) {
+	s := NewTermvectorsService(nil)
+	if err := s.Validate(); err == nil {
+		t.Fatalf("Validate() on an empty service should require Index and Type")
+	}
+
+	s.Index("twitter").Type("tweet")
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil once Index and Type are set", err)
+	}
+}